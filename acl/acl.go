@@ -0,0 +1,175 @@
+// Package acl parses PostgreSQL aclitem strings, the encoding used by
+// pg_class.relacl and the analogous *_acl columns for schemas, sequences
+// and functions, into structured privilege grants.
+package acl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Privileges is a bitmask of the privilege letters used in aclitem
+// strings. See https://www.postgresql.org/docs/current/catalog-pg-class.html
+// for the full list of codes.
+type Privileges uint16
+
+const (
+	Select Privileges = 1 << iota
+	Insert
+	Update
+	Delete
+	Truncate
+	References
+	Trigger
+	Execute
+	Usage
+	Create
+	Connect
+	Temporary
+)
+
+var privilegeChars = map[byte]Privileges{
+	'r': Select,
+	'a': Insert,
+	'w': Update,
+	'd': Delete,
+	'D': Truncate,
+	'x': References,
+	't': Trigger,
+	'X': Execute,
+	'U': Usage,
+	'C': Create,
+	'c': Connect,
+	'T': Temporary,
+}
+
+var privilegeNames = map[Privileges]string{
+	Select:     "SELECT",
+	Insert:     "INSERT",
+	Update:     "UPDATE",
+	Delete:     "DELETE",
+	Truncate:   "TRUNCATE",
+	References: "REFERENCES",
+	Trigger:    "TRIGGER",
+	Execute:    "EXECUTE",
+	Usage:      "USAGE",
+	Create:     "CREATE",
+	Connect:    "CONNECT",
+	Temporary:  "TEMPORARY",
+}
+
+// orderedPrivileges fixes the display order used by Names and Item.Strings.
+var orderedPrivileges = []Privileges{
+	Select, Insert, Update, Delete, Truncate, References, Trigger,
+	Execute, Usage, Create, Connect, Temporary,
+}
+
+// Has reports whether p includes priv.
+func (p Privileges) Has(priv Privileges) bool {
+	return p&priv != 0
+}
+
+// Names returns the privileges set in p as their SQL keywords, in a fixed
+// canonical order.
+func (p Privileges) Names() []string {
+	var names []string
+	for _, priv := range orderedPrivileges {
+		if p.Has(priv) {
+			names = append(names, privilegeNames[priv])
+		}
+	}
+	return names
+}
+
+// Item is one decoded aclitem: a grantee, the privileges it holds, which
+// of those privileges carry WITH GRANT OPTION, and the role that granted
+// them.
+type Item struct {
+	Role         string
+	Privileges   Privileges
+	GrantOptions Privileges
+	GrantedBy    string
+}
+
+// Strings renders Item's privileges the way `rolo list` displays them: a
+// privilege held WITH GRANT OPTION is suffixed with "*", e.g. "SELECT*".
+func (it Item) Strings() []string {
+	var out []string
+	for _, priv := range orderedPrivileges {
+		if !it.Privileges.Has(priv) {
+			continue
+		}
+		name := privilegeNames[priv]
+		if it.GrantOptions.Has(priv) {
+			name += "*"
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// ParseItem parses a single aclitem string, e.g. "alice=arwdDxt*/bob" (role
+// alice, granted by bob, with grant option on TRIGGER) or the PUBLIC form
+// "=r/bob" (empty role name before "=").
+func ParseItem(raw string) (Item, error) {
+	eq := strings.IndexByte(raw, '=')
+	if eq < 0 {
+		return Item{}, fmt.Errorf("acl: malformed aclitem %q: missing '='", raw)
+	}
+	role := raw[:eq]
+	if role == "" {
+		role = "PUBLIC"
+	}
+
+	rest := raw[eq+1:]
+	slash := strings.IndexByte(rest, '/')
+	if slash < 0 {
+		return Item{}, fmt.Errorf("acl: malformed aclitem %q: missing '/'", raw)
+	}
+	privChars, grantedBy := rest[:slash], rest[slash+1:]
+
+	var privs, grantOpts Privileges
+	for i := 0; i < len(privChars); i++ {
+		c := privChars[i]
+		if c == '*' {
+			if i == 0 {
+				return Item{}, fmt.Errorf("acl: malformed aclitem %q: leading '*'", raw)
+			}
+			if p, ok := privilegeChars[privChars[i-1]]; ok {
+				grantOpts |= p
+			}
+			continue
+		}
+		p, ok := privilegeChars[c]
+		if !ok {
+			return Item{}, fmt.Errorf("acl: malformed aclitem %q: unknown privilege code %q", raw, string(c))
+		}
+		privs |= p
+	}
+
+	return Item{Role: role, Privileges: privs, GrantOptions: grantOpts, GrantedBy: grantedBy}, nil
+}
+
+// ParseArray parses a PostgreSQL aclitem[] array literal, e.g.
+// "{alice=arwdDxt*/bob,=r/bob}", into its individual Items.
+func ParseArray(raw string) ([]Item, error) {
+	raw = strings.TrimSpace(raw)
+	raw = strings.TrimPrefix(raw, "{")
+	raw = strings.TrimSuffix(raw, "}")
+	if raw == "" {
+		return nil, nil
+	}
+
+	var items []Item
+	for _, part := range strings.Split(raw, ",") {
+		if part == "" {
+			continue
+		}
+		item, err := ParseItem(part)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
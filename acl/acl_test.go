@@ -0,0 +1,110 @@
+package acl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseItem(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want Item
+	}{
+		{
+			name: "role with grant option on trailing privilege",
+			raw:  "alice=arwdDxt*/bob",
+			want: Item{
+				Role:         "alice",
+				Privileges:   Select | Insert | Update | Delete | Truncate | References | Trigger,
+				GrantOptions: Trigger,
+				GrantedBy:    "bob",
+			},
+		},
+		{
+			name: "grant option on a privilege that isn't last",
+			raw:  "alice=r*w/bob",
+			want: Item{
+				Role:         "alice",
+				Privileges:   Select | Update,
+				GrantOptions: Select,
+				GrantedBy:    "bob",
+			},
+		},
+		{
+			name: "PUBLIC empty role before =",
+			raw:  "=r/bob",
+			want: Item{Role: "PUBLIC", Privileges: Select, GrantedBy: "bob"},
+		},
+		{
+			name: "no privileges at all",
+			raw:  "alice=/bob",
+			want: Item{Role: "alice", GrantedBy: "bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseItem(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseItem(%q) returned error: %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseItem(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseItemMalformed(t *testing.T) {
+	tests := []string{
+		"alice-arwd/bob",  // missing '='
+		"alice=arwd-bob",  // missing '/'
+		"alice=arwZ/bob",  // unknown privilege code
+		"alice=*arwd/bob", // leading '*'
+	}
+
+	for _, raw := range tests {
+		if _, err := ParseItem(raw); err == nil {
+			t.Errorf("ParseItem(%q) returned no error, want one", raw)
+		}
+	}
+}
+
+func TestParseArray(t *testing.T) {
+	items, err := ParseArray("{alice=arwdDxt*/bob,=r/bob}")
+	if err != nil {
+		t.Fatalf("ParseArray returned error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("ParseArray returned %d items, want 2", len(items))
+	}
+	if items[0].Role != "alice" || items[1].Role != "PUBLIC" {
+		t.Errorf("ParseArray roles = %q, %q, want alice, PUBLIC", items[0].Role, items[1].Role)
+	}
+}
+
+func TestParseArrayEmpty(t *testing.T) {
+	items, err := ParseArray("{}")
+	if err != nil {
+		t.Fatalf("ParseArray(\"{}\") returned error: %v", err)
+	}
+	if items != nil {
+		t.Errorf("ParseArray(\"{}\") = %v, want nil", items)
+	}
+}
+
+func TestParseArrayMalformed(t *testing.T) {
+	if _, err := ParseArray("{alice-arwd/bob}"); err == nil {
+		t.Error("ParseArray with a malformed item returned no error, want one")
+	}
+}
+
+func TestItemStrings(t *testing.T) {
+	item := Item{Privileges: Select | Update, GrantOptions: Select}
+	got := item.Strings()
+	want := []string{"SELECT*", "UPDATE"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Strings() = %v, want %v", got, want)
+	}
+}
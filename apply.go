@@ -0,0 +1,476 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alileza/rolo/acl"
+)
+
+// planStatements computes the minimal set of CREATE ROLE / GRANT ROLE /
+// REVOKE ROLE / GRANT / REVOKE / ALTER DEFAULT PRIVILEGES statements needed
+// to reconcile the live cluster with cfg. Role membership is reconciled
+// fully: a parent in role.MemberOf but not currently held is granted, and a
+// live parent absent from role.MemberOf is revoked. Table wildcards in
+// cfg.Grants (e.g. "public.*") are expanded against the live,
+// schema-qualified table list before diffing; every other object type is
+// diffed by exact name.
+func planStatements(db *sql.DB, cfg *Config) ([]string, error) {
+	existingRoles, err := getAllRoles(db)
+	if err != nil {
+		return nil, err
+	}
+	existingRoleSet := make(map[string]bool, len(existingRoles))
+	for _, r := range existingRoles {
+		existingRoleSet[r] = true
+	}
+
+	tablesBySchema, err := getAllTablesBySchema(db)
+	if err != nil {
+		return nil, err
+	}
+	tableACLs, err := loadTableACLsBySchema(db)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := getRoleMemberships(db)
+	if err != nil {
+		return nil, err
+	}
+	currentParentsOf := make(map[string]map[string]bool, len(memberships))
+	for _, e := range memberships {
+		if currentParentsOf[e.Member] == nil {
+			currentParentsOf[e.Member] = make(map[string]bool)
+		}
+		currentParentsOf[e.Member][e.Role] = true
+	}
+
+	var statements []string
+
+	for _, role := range cfg.Roles {
+		if !existingRoleSet[role.Name] {
+			statements = append(statements, fmt.Sprintf(`CREATE ROLE "%s";`, role.Name))
+			existingRoleSet[role.Name] = true
+		}
+
+		desiredParents := make(map[string]bool, len(role.MemberOf))
+		for _, parent := range role.MemberOf {
+			desiredParents[parent] = true
+			if !currentParentsOf[role.Name][parent] {
+				statements = append(statements, fmt.Sprintf(`GRANT "%s" TO "%s";`, parent, role.Name))
+			}
+		}
+		for parent := range currentParentsOf[role.Name] {
+			if !desiredParents[parent] {
+				statements = append(statements, fmt.Sprintf(`REVOKE "%s" FROM "%s";`, parent, role.Name))
+			}
+		}
+	}
+
+	for _, grant := range cfg.Grants {
+		o, err := parseObjectType(grant.ObjectType)
+		if err != nil {
+			return nil, err
+		}
+		desiredPrivs, err := validatePrivileges(o, grant.Privileges)
+		if err != nil {
+			return nil, err
+		}
+
+		objects := grant.Objects
+		if o == objectTable {
+			objects = expandTableWildcards(grant.Objects, tablesBySchema)
+		}
+
+		for _, object := range objects {
+			current, err := currentObjectPrivileges(db, o, object, grant.Role, tableACLs)
+			if err != nil {
+				return nil, err
+			}
+			currentSet := make(map[string]bool, len(current))
+			for _, p := range current {
+				// Drop the "*" grant-option marker so "SELECT*" diffs as SELECT.
+				currentSet[strings.TrimSuffix(p, "*")] = true
+			}
+			desiredSet := make(map[string]bool, len(desiredPrivs))
+			for _, p := range desiredPrivs {
+				desiredSet[p] = true
+			}
+
+			var toGrant, toRevoke []string
+			for _, p := range desiredPrivs {
+				if !currentSet[p] {
+					toGrant = append(toGrant, p)
+				}
+			}
+			for p := range currentSet {
+				if !desiredSet[p] {
+					toRevoke = append(toRevoke, p)
+				}
+			}
+
+			if len(toGrant) > 0 {
+				stmt, err := buildGrantSQL("GRANT", o, toGrant, nil, object, grant.Role, false)
+				if err != nil {
+					return nil, err
+				}
+				statements = append(statements, stmt)
+			}
+			if len(toRevoke) > 0 {
+				stmt, err := buildGrantSQL("REVOKE", o, toRevoke, nil, object, grant.Role, false)
+				if err != nil {
+					return nil, err
+				}
+				statements = append(statements, stmt)
+			}
+		}
+	}
+
+	defaultStatements, err := planDefaultPrivilegeStatements(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+	statements = append(statements, defaultStatements...)
+
+	return statements, nil
+}
+
+// currentObjectPrivileges reports the privileges role currently holds on
+// object, using the same has_*_privilege/ACL introspection rolo uses
+// elsewhere for each object type. For tables, tableACLs is the result of a
+// single loadTableACLsBySchema call made once for every table being diffed, so
+// diffing N grants against M tables costs one query rather than N*M.
+func currentObjectPrivileges(db *sql.DB, o objectType, object, role string, tableACLs map[string][]acl.Item) ([]string, error) {
+	switch o {
+	case objectTable:
+		return buildGrant(tableACLs[object], role, nil, false).Privileges, nil
+	case objectSchema:
+		return hasPrivileges(db, "has_schema_privilege", objectPrivileges[objectSchema], object, role)
+	case objectSequence:
+		return hasPrivileges(db, "has_sequence_privilege", objectPrivileges[objectSequence], object, role)
+	case objectDatabase:
+		return hasPrivileges(db, "has_database_privilege", objectPrivileges[objectDatabase], object, role)
+	case objectType_:
+		return hasPrivileges(db, "has_type_privilege", objectPrivileges[objectType_], object, role)
+	case objectFunction:
+		acls, err := loadFunctionACLs(db, object)
+		if err != nil {
+			return nil, err
+		}
+		return buildGrant(acls[object], role, nil, false).Privileges, nil
+	default:
+		return nil, fmt.Errorf("object type %q is not supported by plan/apply", o)
+	}
+}
+
+// hasPrivileges checks each of privs against object/role using hasFn, one
+// of has_schema_privilege, has_sequence_privilege, has_database_privilege or
+// has_type_privilege,
+// returning the ones currently granted.
+func hasPrivileges(db *sql.DB, hasFn string, privs []string, object, role string) ([]string, error) {
+	var granted []string
+	for _, priv := range privs {
+		query := fmt.Sprintf("SELECT %s($1, $2, '%s')", hasFn, priv)
+		var has bool
+		if err := db.QueryRow(query, role, object).Scan(&has); err != nil {
+			return nil, err
+		}
+		if has {
+			granted = append(granted, priv)
+		}
+	}
+	return granted, nil
+}
+
+// planDefaultPrivilegeStatements diffs cfg.DefaultGrants against the live
+// pg_default_acl entries and returns the ALTER DEFAULT PRIVILEGES
+// statements needed to reconcile them.
+func planDefaultPrivilegeStatements(db *sql.DB, cfg *Config) ([]string, error) {
+	if len(cfg.DefaultGrants) == 0 {
+		return nil, nil
+	}
+
+	liveDefaults, err := getDefaultPrivileges(db)
+	if err != nil {
+		return nil, err
+	}
+	currentByKey := make(map[string]map[string]bool, len(liveDefaults))
+	for _, d := range liveDefaults {
+		key := defaultGrantKey(d.OwnerRole, d.Schema, d.ObjectType, d.Role)
+		set := make(map[string]bool, len(d.Privileges))
+		for _, p := range d.Privileges {
+			set[p] = true
+		}
+		currentByKey[key] = set
+	}
+
+	var statements []string
+	for _, dg := range cfg.DefaultGrants {
+		objType := strings.ToLower(dg.ObjectType)
+		objInfo, ok := defaultPrivilegeObjectTypes[objType]
+		if !ok {
+			return nil, fmt.Errorf("unsupported default-privileges object type %q (expected one of tables, sequences, functions, types)", dg.ObjectType)
+		}
+
+		desiredSet := make(map[string]bool, len(dg.Privileges))
+		for _, p := range dg.Privileges {
+			desiredSet[strings.ToUpper(strings.TrimSpace(p))] = true
+		}
+		currentSet := currentByKey[defaultGrantKey(dg.ForRole, dg.InSchema, objType, dg.Role)]
+
+		var toGrant, toRevoke []string
+		for p := range desiredSet {
+			if !currentSet[p] {
+				toGrant = append(toGrant, p)
+			}
+		}
+		for p := range currentSet {
+			if !desiredSet[p] {
+				toRevoke = append(toRevoke, p)
+			}
+		}
+
+		if len(toGrant) > 0 {
+			statements = append(statements, fmt.Sprintf(
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "%s" IN SCHEMA "%s" GRANT %s ON %s TO "%s";`,
+				dg.ForRole, dg.InSchema, strings.Join(toGrant, ", "), objInfo.keyword, dg.Role,
+			))
+		}
+		if len(toRevoke) > 0 {
+			statements = append(statements, fmt.Sprintf(
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "%s" IN SCHEMA "%s" REVOKE %s ON %s FROM "%s";`,
+				dg.ForRole, dg.InSchema, strings.Join(toRevoke, ", "), objInfo.keyword, dg.Role,
+			))
+		}
+	}
+	return statements, nil
+}
+
+// defaultGrantKey identifies one owner/schema/object-type/role default
+// privilege entry, matching both pg_default_acl rows and DefaultGrantConfig
+// entries so the two can be diffed against each other.
+func defaultGrantKey(forRole, schema, objType, role string) string {
+	return forRole + "\x00" + schema + "\x00" + objType + "\x00" + role
+}
+
+// expandTableWildcards resolves "*" and "<schema>.*" entries against the
+// live, schema-qualified table list, returning every match as
+// "<schema>.<table>" so the schema survives into the emitted GRANT/REVOKE
+// SQL (via qualifiedIdentifier) instead of being lost once flattened into a
+// bare name - otherwise two schemas with a same-named table could have the
+// statement land on the wrong one via search_path. "*" matches every table
+// in the cluster; "<schema>.*" is scoped to tablesBySchema[schema] only, so
+// "public.*" doesn't also pull in tables from other schemas. An exact,
+// non-wildcard pattern is qualified too when it unambiguously names a table
+// in exactly one schema; otherwise it's left as given.
+func expandTableWildcards(patterns []string, tablesBySchema map[string][]string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	add := func(t string) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	schemas := make([]string, 0, len(tablesBySchema))
+	for schema := range tablesBySchema {
+		schemas = append(schemas, schema)
+	}
+	sort.Strings(schemas)
+
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			for _, schema := range schemas {
+				for _, t := range tablesBySchema[schema] {
+					add(schema + "." + t)
+				}
+			}
+			continue
+		}
+		if schema, ok := strings.CutSuffix(pattern, ".*"); ok {
+			for _, t := range tablesBySchema[schema] {
+				add(schema + "." + t)
+			}
+			continue
+		}
+		add(qualifyTableName(pattern, schemas, tablesBySchema))
+	}
+	return out
+}
+
+// qualifyTableName schema-qualifies name when it unambiguously identifies a
+// table in exactly one schema; a name that's already "schema.table", or
+// that matches zero or multiple schemas, is returned unchanged.
+func qualifyTableName(name string, schemas []string, tablesBySchema map[string][]string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	match := ""
+	for _, schema := range schemas {
+		for _, t := range tablesBySchema[schema] {
+			if t == name {
+				if match != "" {
+					return name
+				}
+				match = schema
+			}
+		}
+	}
+	if match == "" {
+		return name
+	}
+	return match + "." + name
+}
+
+// runPlan prints the statements needed to reconcile dsn with the config at
+// path, without executing them.
+func runPlan(dsn, path string) error {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statements, err := planStatements(db, cfg)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		fmt.Println("No changes. Cluster matches the desired state.")
+		return nil
+	}
+	fmt.Printf("Plan: %d statement(s) to reconcile the cluster:\n\n", len(statements))
+	for _, s := range statements {
+		fmt.Printf("  %s\n", s)
+	}
+	return nil
+}
+
+// runApply reconciles dsn with the config at path, executing the planned
+// statements inside a single transaction so a partial failure rolls back
+// cleanly. lockTimeout is passed to `SET LOCAL lock_timeout` to bound how
+// long apply will wait on conflicting locks (e.g. "5s").
+func runApply(dsn, path, lockTimeout string) error {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statements, err := planStatements(db, cfg)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		fmt.Println("No changes. Cluster matches the desired state.")
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if lockTimeout != "" {
+		if _, err := tx.Exec(fmt.Sprintf("SET LOCAL lock_timeout = '%s';", lockTimeout)); err != nil {
+			return fmt.Errorf("failed to set lock_timeout: %w", err)
+		}
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to apply %q: %w", stmt, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	fmt.Printf("Applied %d statement(s).\n", len(statements))
+	return nil
+}
+
+// runExport dumps the current cluster state (roles, table grants and
+// default privileges) into the same YAML schema accepted by plan/apply, so
+// existing databases can be adopted into a declarative config.
+func runExport(dsn, path string) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	roles, err := getAllRoles(db)
+	if err != nil {
+		return err
+	}
+	tables, err := getAllTables(db)
+	if err != nil {
+		return err
+	}
+	tableACLs, err := loadTableACLs(db, "")
+	if err != nil {
+		return err
+	}
+	memberships, err := getRoleMemberships(db)
+	if err != nil {
+		return err
+	}
+	parentsOf := make(map[string][]string, len(memberships))
+	for _, e := range memberships {
+		parentsOf[e.Member] = append(parentsOf[e.Member], e.Role)
+	}
+
+	cfg := &Config{}
+	for _, role := range roles {
+		cfg.Roles = append(cfg.Roles, RoleConfig{Name: role, MemberOf: parentsOf[role]})
+
+		for _, table := range tables {
+			perms := buildGrant(tableACLs[table], role, nil, false).Privileges
+			if len(perms) == 0 {
+				continue
+			}
+			for i := range perms {
+				perms[i] = strings.TrimSuffix(perms[i], "*")
+			}
+			cfg.Grants = append(cfg.Grants, GrantConfig{
+				Role:       role,
+				ObjectType: string(objectTable),
+				Objects:    []string{table},
+				Privileges: perms,
+			})
+		}
+	}
+
+	defaults, err := getDefaultPrivileges(db)
+	if err != nil {
+		return err
+	}
+	for _, def := range defaults {
+		cfg.DefaultGrants = append(cfg.DefaultGrants, DefaultGrantConfig{
+			Role:       def.Role,
+			ForRole:    def.OwnerRole,
+			InSchema:   def.Schema,
+			ObjectType: def.ObjectType,
+			Privileges: def.Privileges,
+		})
+	}
+
+	if err := writeConfig(path, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d role(s), %d grant(s) and %d default grant(s) to %s\n", len(cfg.Roles), len(cfg.Grants), len(cfg.DefaultGrants), path)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestExpandTableWildcards(t *testing.T) {
+	tablesBySchema := map[string][]string{
+		"public":    {"orders", "users"},
+		"reporting": {"orders", "daily_totals"},
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name:     "star matches every table, schema-qualified",
+			patterns: []string{"*"},
+			want:     []string{"public.orders", "public.users", "reporting.daily_totals", "reporting.orders"},
+		},
+		{
+			name:     "schema wildcard scoped to that schema only",
+			patterns: []string{"reporting.*"},
+			want:     []string{"reporting.daily_totals", "reporting.orders"},
+		},
+		{
+			name:     "exact name unambiguous across schemas is qualified",
+			patterns: []string{"users"},
+			want:     []string{"public.users"},
+		},
+		{
+			name:     "exact name ambiguous across schemas is left bare",
+			patterns: []string{"orders"},
+			want:     []string{"orders"},
+		},
+		{
+			name:     "already-qualified exact name is untouched",
+			patterns: []string{"reporting.orders"},
+			want:     []string{"reporting.orders"},
+		},
+		{
+			name:     "duplicates across patterns are deduplicated",
+			patterns: []string{"reporting.*", "reporting.orders"},
+			want:     []string{"reporting.daily_totals", "reporting.orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandTableWildcards(tt.patterns, tablesBySchema)
+			sort.Strings(got)
+			want := append([]string{}, tt.want...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expandTableWildcards(%v) = %v, want %v", tt.patterns, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the declarative, desired-state document read by `rolo plan`,
+// `rolo apply` and written by `rolo export`. It intentionally mirrors the
+// object types rolo already knows how to grant/revoke.
+type Config struct {
+	Roles         []RoleConfig         `yaml:"roles,omitempty"`
+	Grants        []GrantConfig        `yaml:"grants,omitempty"`
+	DefaultGrants []DefaultGrantConfig `yaml:"default_grants,omitempty"`
+}
+
+// RoleConfig describes a role and the parent roles it should inherit from
+// (GRANT <member_of> TO <name>).
+type RoleConfig struct {
+	Name     string   `yaml:"name"`
+	MemberOf []string `yaml:"member_of,omitempty"`
+}
+
+// GrantConfig describes the desired privileges a role holds on a set of
+// objects of ObjectType (table, schema, sequence, function, database or
+// type). For tables, Objects supports the wildcards "*" (every table) and
+// "<schema>.*" (e.g. "public.*"), expanded at plan time against the live,
+// schema-qualified table list; other patterns containing "*" are not
+// expanded. An exact table name is schema-qualified automatically when
+// it names a table in exactly one schema, so the emitted GRANT/REVOKE can't
+// land on a same-named table in the wrong schema. Every other object type
+// is matched by name as-is.
+type GrantConfig struct {
+	Role       string   `yaml:"role"`
+	ObjectType string   `yaml:"object_type"`
+	Objects    []string `yaml:"objects,omitempty"`
+	Privileges []string `yaml:"privileges"`
+}
+
+// DefaultGrantConfig describes the desired default privileges for objects
+// that ForRole creates in InSchema, reconciled via ALTER DEFAULT
+// PRIVILEGES. ObjectType is one of the keys of defaultPrivilegeObjectTypes
+// (tables, sequences, functions, types).
+type DefaultGrantConfig struct {
+	Role       string   `yaml:"role"`
+	ForRole    string   `yaml:"for_role"`
+	InSchema   string   `yaml:"in_schema"`
+	ObjectType string   `yaml:"object_type"`
+	Privileges []string `yaml:"privileges"`
+}
+
+// loadConfig reads and parses a declarative permissions document from path.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// writeConfig serializes cfg as YAML to path, used by `rolo export`.
+func writeConfig(path string, cfg *Config) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/alileza/rolo/acl"
+)
+
+// defaultPrivilegeObjectTypes maps the --object-type values accepted by
+// `rolo default-privileges` to the keyword PostgreSQL expects after ON in
+// ALTER DEFAULT PRIVILEGES ... GRANT ... ON <keyword> TO ..., and to the
+// single-character defaclobjtype stored in pg_default_acl.
+var defaultPrivilegeObjectTypes = map[string]struct {
+	keyword string
+	code    byte
+	objType objectType
+}{
+	"tables":    {"TABLES", 'r', objectTable},
+	"sequences": {"SEQUENCES", 'S', objectSequence},
+	"functions": {"FUNCTIONS", 'f', objectFunction},
+	"types":     {"TYPES", 'T', objectType_},
+}
+
+// defaultPrivileges implements `rolo default-privileges`, wrapping
+// ALTER DEFAULT PRIVILEGES FOR ROLE <for-role> IN SCHEMA <in-schema>
+// GRANT|REVOKE <permissions> ON <object-type> TO|FROM <role>.
+func defaultPrivileges(dsn, role, forRole, inSchema, objType, perms string, grantOption, revoke bool) error {
+	objType = strings.ToLower(objType)
+	objInfo, ok := defaultPrivilegeObjectTypes[objType]
+	if !ok {
+		return fmt.Errorf("unsupported object type %q (expected one of tables, sequences, functions, types)", objType)
+	}
+
+	permsList, err := validatePrivileges(objInfo.objType, strings.Split(perms, ","))
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var stmt string
+	if revoke {
+		stmt = fmt.Sprintf(
+			`ALTER DEFAULT PRIVILEGES FOR ROLE "%s" IN SCHEMA "%s" REVOKE %s ON %s FROM "%s";`,
+			forRole, inSchema, strings.Join(permsList, ", "), objInfo.keyword, role,
+		)
+	} else {
+		withGrantOption := ""
+		if grantOption {
+			withGrantOption = " WITH GRANT OPTION"
+		}
+		stmt = fmt.Sprintf(
+			`ALTER DEFAULT PRIVILEGES FOR ROLE "%s" IN SCHEMA "%s" GRANT %s ON %s TO "%s"%s;`,
+			forRole, inSchema, strings.Join(permsList, ", "), objInfo.keyword, role, withGrantOption,
+		)
+	}
+
+	if _, err := db.Exec(stmt); err != nil {
+		return fmt.Errorf("failed to alter default privileges: %w", err)
+	}
+
+	verb := "Granted"
+	if revoke {
+		verb = "Revoked"
+	}
+	fmt.Printf("%s default %s on future %s in schema %s (for role %s) to/from %s\n",
+		verb, strings.Join(permsList, ", "), objInfo.keyword, inSchema, forRole, role)
+	return nil
+}
+
+// defaultACLEntry is one row decoded from pg_default_acl, scoped to the
+// owner/schema/object-type it applies to.
+type defaultACLEntry struct {
+	OwnerRole  string
+	Schema     string
+	ObjectType string
+	Role       string
+	Privileges []string
+}
+
+// getDefaultPrivileges reads pg_default_acl and returns the default
+// privileges configured for each owner/schema/object-type, decoded via the
+// acl package (the same aclitem parser used for tables, schemas, sequences,
+// functions and databases) for display alongside `rolo list` and for
+// diffing in plan/apply.
+func getDefaultPrivileges(db *sql.DB) ([]defaultACLEntry, error) {
+	rows, err := db.Query(`
+		SELECT a.rolname, COALESCE(n.nspname, ''), d.defaclobjtype, d.defaclacl::text
+		FROM pg_default_acl d
+		JOIN pg_roles a ON a.oid = d.defaclrole
+		LEFT JOIN pg_namespace n ON n.oid = d.defaclnamespace
+		ORDER BY a.rolname, n.nspname, d.defaclobjtype;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objTypeNames := map[byte]string{'r': "tables", 'S': "sequences", 'f': "functions", 'T': "types"}
+
+	var entries []defaultACLEntry
+	for rows.Next() {
+		var ownerRole, schema, objType string
+		var rawACL sql.NullString
+		if err := rows.Scan(&ownerRole, &schema, &objType, &rawACL); err != nil {
+			return nil, err
+		}
+		if !rawACL.Valid {
+			continue
+		}
+
+		items, err := acl.ParseArray(rawACL.String)
+		if err != nil {
+			return nil, err
+		}
+		objTypeName := objTypeNames[objType[0]]
+		for _, item := range items {
+			entries = append(entries, defaultACLEntry{
+				OwnerRole:  ownerRole,
+				Schema:     schema,
+				ObjectType: objTypeName,
+				Role:       item.Role,
+				Privileges: item.Privileges.Names(),
+			})
+		}
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dialect selects which SQL surface rolo talks against. CockroachDB is
+// wire-compatible with PostgreSQL but diverges on privilege introspection
+// and adds cluster-wide "system" privileges PostgreSQL has no equivalent
+// for.
+type dialect string
+
+const (
+	dialectPostgres  dialect = "postgres"
+	dialectCockroach dialect = "cockroach"
+)
+
+// cockroachSystemPrivileges lists the CockroachDB system privileges
+// grantable via GRANT SYSTEM <priv> TO <role>. See
+// https://www.cockroachlabs.com/docs/stable/security-reference/authorization#supported-privileges.
+var cockroachSystemPrivileges = []string{
+	"BACKUP", "RESTORE", "CANCELQUERY", "CONTROLJOB", "MODIFYCLUSTERSETTING",
+	"VIEWACTIVITY", "VIEWCLUSTERSETTING", "NOSQLLOGIN", "REPLICATION", "EXTERNALCONNECTION",
+}
+
+// clusterVersion is a CockroachDB "major.minor" release, e.g. 23.1 or
+// 23.10. It's kept as separate integers rather than a float so that, say,
+// 23.1 and 23.10 compare correctly instead of both parsing to 23.1.
+type clusterVersion struct {
+	Major int
+	Minor int
+}
+
+// before reports whether v is an earlier release than other.
+func (v clusterVersion) before(other clusterVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	return v.Minor < other.Minor
+}
+
+func (v clusterVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// cockroachSystemPrivilegesMinVersion is the earliest CockroachDB release
+// that supports GRANT SYSTEM ... / crdb_internal.cluster_privileges.
+var cockroachSystemPrivilegesMinVersion = clusterVersion{Major: 22, Minor: 2}
+
+// detectDialect runs SELECT version() and classifies the server as
+// postgres or cockroach. It's used when --dialect is left as "auto".
+func detectDialect(db *sql.DB) (dialect, error) {
+	var version string
+	if err := db.QueryRow("SELECT version();").Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to detect dialect: %w", err)
+	}
+	if strings.Contains(version, "CockroachDB") {
+		return dialectCockroach, nil
+	}
+	return dialectPostgres, nil
+}
+
+// resolveDialect returns the configured dialect, auto-detecting against db
+// when flag is empty or "auto".
+func resolveDialect(db *sql.DB, flag string) (dialect, error) {
+	switch strings.ToLower(flag) {
+	case "", "auto":
+		return detectDialect(db)
+	case string(dialectPostgres):
+		return dialectPostgres, nil
+	case string(dialectCockroach):
+		return dialectCockroach, nil
+	default:
+		return "", fmt.Errorf("unsupported dialect %q (expected postgres, cockroach or auto)", flag)
+	}
+}
+
+// cockroachClusterVersion reads the CockroachDB cluster setting
+// version to version-gate features such as system
+// privileges that only exist on newer releases.
+func cockroachClusterVersion(db *sql.DB) (clusterVersion, error) {
+	var raw string
+	if err := db.QueryRow("SHOW CLUSTER SETTING version;").Scan(&raw); err != nil {
+		return clusterVersion{}, fmt.Errorf("failed to read cluster version: %w", err)
+	}
+	return parseClusterVersion(raw)
+}
+
+// parseClusterVersion parses a CockroachDB version string, e.g. "22.2" or
+// "23.1-5", into its major.minor parts. Parsing major and minor separately
+// (rather than as one float) matters because a two-digit minor like "23.10"
+// would otherwise collapse to the same float64 as "23.1".
+func parseClusterVersion(raw string) (clusterVersion, error) {
+	// raw looks like "22.2" or "23.1-5"; keep the major.minor prefix.
+	majorMinor := strings.SplitN(raw, "-", 2)[0]
+	dot := strings.IndexByte(majorMinor, '.')
+	if dot < 0 {
+		return clusterVersion{}, fmt.Errorf("failed to parse cluster version %q: missing '.'", raw)
+	}
+	major, err := strconv.Atoi(majorMinor[:dot])
+	if err != nil {
+		return clusterVersion{}, fmt.Errorf("failed to parse cluster version %q: %w", raw, err)
+	}
+	minor, err := strconv.Atoi(majorMinor[dot+1:])
+	if err != nil {
+		return clusterVersion{}, fmt.Errorf("failed to parse cluster version %q: %w", raw, err)
+	}
+	return clusterVersion{Major: major, Minor: minor}, nil
+}
+
+// grantSystemPrivilege implements `rolo grant system`, emitting
+// GRANT SYSTEM <priv> TO <role> against a CockroachDB cluster. It rejects
+// running against PostgreSQL or against a CockroachDB cluster too old to
+// support system privileges.
+func grantSystemPrivilege(dsn, dialectFlag, role, perms string) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	d, err := resolveDialect(db, dialectFlag)
+	if err != nil {
+		return err
+	}
+	if d != dialectCockroach {
+		return fmt.Errorf("system privileges are a CockroachDB feature; connected cluster is %s", d)
+	}
+
+	version, err := cockroachClusterVersion(db)
+	if err != nil {
+		return err
+	}
+	if version.before(cockroachSystemPrivilegesMinVersion) {
+		return fmt.Errorf("system privileges require CockroachDB >= %s, cluster is running %s", cockroachSystemPrivilegesMinVersion, version)
+	}
+
+	permsList := strings.Split(perms, ",")
+	for i := range permsList {
+		p := strings.ToUpper(strings.TrimSpace(permsList[i]))
+		if !contains(cockroachSystemPrivileges, p) {
+			return fmt.Errorf("%q is not a recognized CockroachDB system privilege (expected one of %s)", p, strings.Join(cockroachSystemPrivileges, ", "))
+		}
+		permsList[i] = p
+	}
+
+	stmt := fmt.Sprintf(`GRANT SYSTEM %s TO "%s";`, strings.Join(permsList, ", "), role)
+	if _, err := db.Exec(stmt); err != nil {
+		return fmt.Errorf("failed to grant system privileges: %w", err)
+	}
+
+	fmt.Printf("Granted system %s to %s\n", strings.Join(permsList, ", "), role)
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// systemPrivilegeEntry is one role/privilege pair read from
+// crdb_internal.cluster_privileges.
+type systemPrivilegeEntry struct {
+	Role      string
+	Privilege string
+}
+
+// getSystemPrivileges reads crdb_internal.cluster_privileges, CockroachDB's
+// system-privilege equivalent of pg_class.relacl.
+func getSystemPrivileges(db *sql.DB) ([]systemPrivilegeEntry, error) {
+	rows, err := db.Query(`SELECT grantee, privilege_type FROM crdb_internal.cluster_privileges ORDER BY grantee, privilege_type;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []systemPrivilegeEntry
+	for rows.Next() {
+		var e systemPrivilegeEntry
+		if err := rows.Scan(&e.Role, &e.Privilege); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// getTablePermissionsForRoleCockroach returns role's privileges on table
+// for CockroachDB, which resolves privileges via SHOW GRANTS ON rather
+// than the pg_class.relacl decoding loadTableACLs does for PostgreSQL.
+func getTablePermissionsForRoleCockroach(db *sql.DB, table, role string) (string, error) {
+	rows, err := db.Query(fmt.Sprintf(`SHOW GRANTS ON TABLE "%s" FOR "%s";`, table, role))
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	privIdx := -1
+	for i, c := range cols {
+		if c == "privilege_type" {
+			privIdx = i
+		}
+	}
+	if privIdx < 0 {
+		return "", fmt.Errorf("unexpected SHOW GRANTS columns: %v", cols)
+	}
+
+	var granted []string
+	for rows.Next() {
+		values := make([]interface{}, len(cols))
+		scanTargets := make([]interface{}, len(cols))
+		for i := range values {
+			scanTargets[i] = &values[i]
+		}
+		if err := rows.Scan(scanTargets...); err != nil {
+			return "", err
+		}
+		if priv, ok := values[privIdx].([]byte); ok {
+			granted = append(granted, string(priv))
+		} else if priv, ok := values[privIdx].(string); ok {
+			granted = append(granted, priv)
+		}
+	}
+	return strings.Join(granted, ", "), rows.Err()
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestParseClusterVersion(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want clusterVersion
+	}{
+		{raw: "22.2", want: clusterVersion{Major: 22, Minor: 2}},
+		{raw: "23.1", want: clusterVersion{Major: 23, Minor: 1}},
+		{raw: "23.10", want: clusterVersion{Major: 23, Minor: 10}},
+		{raw: "23.1-5", want: clusterVersion{Major: 23, Minor: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseClusterVersion(tt.raw)
+			if err != nil {
+				t.Fatalf("parseClusterVersion(%q) returned error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseClusterVersion(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClusterVersionMalformed(t *testing.T) {
+	for _, raw := range []string{"", "23", "a.b"} {
+		if _, err := parseClusterVersion(raw); err == nil {
+			t.Errorf("parseClusterVersion(%q) returned no error, want one", raw)
+		}
+	}
+}
+
+func TestClusterVersionBefore(t *testing.T) {
+	v231 := clusterVersion{Major: 23, Minor: 1}
+	v2310 := clusterVersion{Major: 23, Minor: 10}
+
+	if !v231.before(v2310) {
+		t.Errorf("%s.before(%s) = false, want true", v231, v2310)
+	}
+	if v2310.before(v231) {
+		t.Errorf("%s.before(%s) = true, want false", v2310, v231)
+	}
+	if v231.before(v231) {
+		t.Errorf("%s.before(%s) = true, want false", v231, v231)
+	}
+}
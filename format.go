@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// permissionSchemaVersion identifies the shape of permissionDocument so
+// downstream tools (jq, CI diffs) can detect breaking changes.
+const permissionSchemaVersion = "rolo.v1"
+
+// permissionRow is one role/object/privilege-set tuple, the common shape
+// every `rolo list` output format renders from.
+type permissionRow struct {
+	Role        string   `json:"role" yaml:"role"`
+	Schema      string   `json:"schema" yaml:"schema"`
+	ObjectType  string   `json:"object_type" yaml:"object_type"`
+	Object      string   `json:"object" yaml:"object"`
+	Privileges  []string `json:"privileges" yaml:"privileges"`
+	GrantOption bool     `json:"grant_option" yaml:"grant_option"`
+	GrantedBy   string   `json:"granted_by" yaml:"granted_by"`
+}
+
+// permissionDocument wraps rows with a schema version for JSON/YAML output.
+type permissionDocument struct {
+	Schema string          `json:"schema" yaml:"schema"`
+	Rows   []permissionRow `json:"rows" yaml:"rows"`
+}
+
+// renderPermissions dispatches to the formatter selected by --output.
+func renderPermissions(rows []permissionRow, output string) error {
+	switch strings.ToLower(output) {
+	case "", "table":
+		return renderPermissionsTable(rows)
+	case "json":
+		return renderPermissionsJSON(rows)
+	case "yaml":
+		return renderPermissionsYAML(rows)
+	case "csv":
+		return renderPermissionsDelimited(rows, ',')
+	case "tsv":
+		return renderPermissionsDelimited(rows, '\t')
+	default:
+		return fmt.Errorf("unsupported output format %q (expected table, json, csv, tsv, yaml)", output)
+	}
+}
+
+func renderPermissionsTable(rows []permissionRow) error {
+	fmt.Printf("%-20s %-14s %-20s %-50s\n", "Role", "ObjectType", "Object", "Permissions")
+	fmt.Println(strings.Repeat("-", 104))
+	for _, r := range rows {
+		perms := strings.Join(r.Privileges, ", ")
+		if perms == "" {
+			perms = "<no access>"
+		}
+		fmt.Printf("%-20s %-14s %-20s %-50s\n", r.Role, r.ObjectType, r.Object, perms)
+	}
+	return nil
+}
+
+func renderPermissionsJSON(rows []permissionRow) error {
+	doc := permissionDocument{Schema: permissionSchemaVersion, Rows: rows}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func renderPermissionsYAML(rows []permissionRow) error {
+	doc := permissionDocument{Schema: permissionSchemaVersion, Rows: rows}
+	enc := yaml.NewEncoder(os.Stdout)
+	defer enc.Close()
+	return enc.Encode(doc)
+}
+
+func renderPermissionsDelimited(rows []permissionRow, delim rune) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = delim
+	defer w.Flush()
+
+	if err := w.Write([]string{"role", "schema", "object_type", "object", "privileges", "grant_option", "granted_by"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := w.Write([]string{
+			r.Role, r.Schema, r.ObjectType, r.Object,
+			strings.Join(r.Privileges, "|"),
+			fmt.Sprintf("%t", r.GrantOption),
+			r.GrantedBy,
+		}); err != nil {
+			return err
+		}
+	}
+	return w.Error()
+}
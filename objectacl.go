@@ -0,0 +1,243 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/alileza/rolo/acl"
+)
+
+// objectGrant describes what `rolo list` shows for one role/object pair:
+// its effective privileges (starred when held WITH GRANT OPTION, and
+// annotated with the ancestor role they were inherited from when
+// showInherited is set), plus grant-option/granted-by attribution for
+// privileges granted directly to the role or to PUBLIC. It's shared by
+// every object type rolo introspects (tables, schemas, sequences,
+// functions, databases), since they all boil down to resolving the same
+// acl.Item list against a role and its inheritance closure.
+type objectGrant struct {
+	Privileges  []string
+	GrantOption bool
+	GrantedBy   string
+}
+
+// buildGrant resolves role's privileges from items, the decoded aclitem
+// list for one object (e.g. aclsByTable[table]), and ancestors, role's
+// pre-resolved inheritance closure (computed once per role rather than
+// once per role/object pair).
+func buildGrant(items []acl.Item, role string, ancestors []string, showInherited bool) objectGrant {
+	var roleItem, publicItem *acl.Item
+	for i := range items {
+		switch items[i].Role {
+		case role:
+			roleItem = &items[i]
+		case "PUBLIC":
+			publicItem = &items[i]
+		}
+	}
+
+	var grant objectGrant
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			grant.Privileges = append(grant.Privileges, name)
+		}
+	}
+
+	if roleItem != nil {
+		for _, s := range roleItem.Strings() {
+			add(s)
+		}
+		if roleItem.GrantOptions != 0 {
+			grant.GrantOption = true
+		}
+		grant.GrantedBy = roleItem.GrantedBy
+	}
+	if publicItem != nil {
+		for _, s := range publicItem.Strings() {
+			add(s)
+		}
+		if grant.GrantedBy == "" {
+			grant.GrantedBy = publicItem.GrantedBy
+		}
+	}
+
+	// Privileges held only through role inheritance are folded into
+	// Privileges unconditionally, since they're part of what the role can
+	// actually do; showInherited only controls whether each one is
+	// annotated with the ancestor it came from.
+	for _, ancestor := range ancestors {
+		for i := range items {
+			if items[i].Role != ancestor {
+				continue
+			}
+			for _, name := range items[i].Privileges.Names() {
+				if seen[name] || seen[name+"*"] {
+					continue
+				}
+				if showInherited {
+					add(fmt.Sprintf("%s(from:%s)", name, ancestor))
+				} else {
+					add(name)
+				}
+			}
+		}
+	}
+
+	return grant
+}
+
+// scanACLRows decodes the (name, acl text) rows shared by loadTableACLs and
+// its schema/sequence/function/database counterparts.
+func scanACLRows(rows *sql.Rows) (map[string][]acl.Item, error) {
+	defer rows.Close()
+
+	acls := make(map[string][]acl.Item)
+	for rows.Next() {
+		var name string
+		var raw sql.NullString
+		if err := rows.Scan(&name, &raw); err != nil {
+			return nil, err
+		}
+		if !raw.Valid {
+			acls[name] = nil
+			continue
+		}
+		items, err := acl.ParseArray(raw.String)
+		if err != nil {
+			return nil, err
+		}
+		acls[name] = items
+	}
+	return acls, rows.Err()
+}
+
+// loadSchemaACLs reads and decodes pg_namespace.nspacl for every
+// user-visible schema in one round trip, the schema-level equivalent of
+// loadTableACLs.
+func loadSchemaACLs(db *sql.DB, schemaFilter string) (map[string][]acl.Item, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname, n.nspacl::text
+		FROM pg_namespace n
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND n.nspname NOT LIKE 'pg\_%'
+		  AND ($1 = '' OR n.nspname = $1);
+	`, schemaFilter)
+	if err != nil {
+		return nil, err
+	}
+	return scanACLRows(rows)
+}
+
+// loadSequenceACLs reads and decodes pg_class.relacl for every sequence in
+// one round trip, the sequence-level equivalent of loadTableACLs.
+func loadSequenceACLs(db *sql.DB, sequenceFilter string) (map[string][]acl.Item, error) {
+	rows, err := db.Query(`
+		SELECT c.relname, c.relacl::text
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind = 'S'
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND ($1 = '' OR c.relname = $1);
+	`, sequenceFilter)
+	if err != nil {
+		return nil, err
+	}
+	return scanACLRows(rows)
+}
+
+// loadFunctionACLs reads and decodes pg_proc.proacl for every function in
+// one round trip, the function-level equivalent of loadTableACLs.
+func loadFunctionACLs(db *sql.DB, functionFilter string) (map[string][]acl.Item, error) {
+	rows, err := db.Query(`
+		SELECT p.proname, p.proacl::text
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND ($1 = '' OR p.proname = $1);
+	`, functionFilter)
+	if err != nil {
+		return nil, err
+	}
+	return scanACLRows(rows)
+}
+
+// loadDatabaseACLs reads and decodes pg_database.datacl for every
+// non-template database in one round trip, the database-level equivalent
+// of loadTableACLs.
+func loadDatabaseACLs(db *sql.DB, databaseFilter string) (map[string][]acl.Item, error) {
+	rows, err := db.Query(`
+		SELECT d.datname, d.datacl::text
+		FROM pg_database d
+		WHERE d.datistemplate = false
+		  AND ($1 = '' OR d.datname = $1);
+	`, databaseFilter)
+	if err != nil {
+		return nil, err
+	}
+	return scanACLRows(rows)
+}
+
+// queryNames runs a single-column name query and collects the results, the
+// shared implementation behind getAllSchemas, getAllSequences,
+// getAllFunctions and getAllDatabases.
+func queryNames(db *sql.DB, query string) ([]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// getAllSchemas lists every user-visible schema, the schema-level
+// equivalent of getAllTables.
+func getAllSchemas(db *sql.DB) ([]string, error) {
+	return queryNames(db, `
+		SELECT nspname FROM pg_namespace
+		WHERE nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND nspname NOT LIKE 'pg\_%'
+		ORDER BY nspname;
+	`)
+}
+
+// getAllSequences lists every sequence, the sequence-level equivalent of
+// getAllTables.
+func getAllSequences(db *sql.DB) ([]string, error) {
+	return queryNames(db, `
+		SELECT c.relname
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind = 'S'
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY c.relname;
+	`)
+}
+
+// getAllFunctions lists every function, the function-level equivalent of
+// getAllTables.
+func getAllFunctions(db *sql.DB) ([]string, error) {
+	return queryNames(db, `
+		SELECT p.proname
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY p.proname;
+	`)
+}
+
+// getAllDatabases lists every non-template database, the database-level
+// equivalent of getAllTables.
+func getAllDatabases(db *sql.DB) ([]string, error) {
+	return queryNames(db, `SELECT datname FROM pg_database WHERE datistemplate = false ORDER BY datname;`)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// objectType identifies the kind of database object a GRANT/REVOKE targets.
+// It controls both which privileges are valid and how the SQL is rendered.
+type objectType string
+
+const (
+	objectTable    objectType = "table"
+	objectSchema   objectType = "schema"
+	objectSequence objectType = "sequence"
+	objectFunction objectType = "function"
+	objectDatabase objectType = "database"
+	objectType_    objectType = "type"
+)
+
+// objectPrivileges lists the privileges PostgreSQL recognizes for each
+// object type. See https://www.postgresql.org/docs/current/sql-grant.html.
+var objectPrivileges = map[objectType][]string{
+	objectTable:    {"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+	objectSchema:   {"USAGE", "CREATE"},
+	objectSequence: {"USAGE", "SELECT", "UPDATE"},
+	objectFunction: {"EXECUTE"},
+	objectDatabase: {"CONNECT", "CREATE", "TEMPORARY"},
+	objectType_:    {"USAGE"},
+}
+
+// sqlKeyword returns the keyword PostgreSQL expects after ON in a GRANT or
+// REVOKE statement for this object type (e.g. "TABLE", "FUNCTION").
+func (o objectType) sqlKeyword() (string, error) {
+	switch o {
+	case objectTable:
+		return "TABLE", nil
+	case objectSchema:
+		return "SCHEMA", nil
+	case objectSequence:
+		return "SEQUENCE", nil
+	case objectFunction:
+		return "FUNCTION", nil
+	case objectDatabase:
+		return "DATABASE", nil
+	case objectType_:
+		return "TYPE", nil
+	default:
+		return "", fmt.Errorf("unknown object type %q", o)
+	}
+}
+
+// parseObjectType normalizes and validates a --object-type flag value.
+func parseObjectType(s string) (objectType, error) {
+	if s == "" {
+		s = "table"
+	}
+	o := objectType(strings.ToLower(s))
+	if _, ok := objectPrivileges[o]; !ok {
+		return "", fmt.Errorf("unsupported object type %q (expected one of table, schema, sequence, function, database, type)", s)
+	}
+	return o, nil
+}
+
+// validatePrivileges checks that every privilege in perms is valid for the
+// given object type, returning the normalized (upper-cased) list.
+func validatePrivileges(o objectType, perms []string) ([]string, error) {
+	allowed := objectPrivileges[o]
+	normalized := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.ToUpper(strings.TrimSpace(p))
+		var ok bool
+		for _, a := range allowed {
+			if a == p {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("privilege %q is not valid for object type %q (allowed: %s)", p, o, strings.Join(allowed, ", "))
+		}
+		normalized = append(normalized, p)
+	}
+	return normalized, nil
+}
+
+// buildGrantSQL renders a GRANT or REVOKE statement for o. verb must be
+// "GRANT" or "REVOKE". When columns is non-empty, privileges are scoped to
+// those columns; this is only meaningful for objectTable. When
+// grantOption is set, a GRANT gets a trailing WITH GRANT OPTION, and a
+// REVOKE only revokes the grant option itself (REVOKE GRANT OPTION FOR),
+// leaving the underlying privilege in place.
+func buildGrantSQL(verb string, o objectType, perms, columns []string, object, role string, grantOption bool) (string, error) {
+	keyword, err := o.sqlKeyword()
+	if err != nil {
+		return "", err
+	}
+
+	privList := strings.Join(perms, ", ")
+	if len(columns) > 0 {
+		if o != objectTable {
+			return "", fmt.Errorf("column privileges are only supported for tables, got object type %q", o)
+		}
+		quotedCols := make([]string, len(columns))
+		for i, c := range columns {
+			quotedCols[i] = fmt.Sprintf(`"%s"`, c)
+		}
+		colList := strings.Join(quotedCols, ", ")
+
+		// PostgreSQL scopes column privileges per-privilege, e.g.
+		// "SELECT (a, b), UPDATE (a, b)". Appending the column list once to
+		// the whole joined privilege list instead would only scope the last
+		// privilege to those columns and silently grant the rest table-wide.
+		scopedPerms := make([]string, len(perms))
+		for i, p := range perms {
+			scopedPerms[i] = fmt.Sprintf("%s (%s)", p, colList)
+		}
+		privList = strings.Join(scopedPerms, ", ")
+	}
+
+	identifier := qualifiedIdentifier(object)
+
+	if verb == "REVOKE" {
+		if grantOption {
+			return fmt.Sprintf(`REVOKE GRANT OPTION FOR %s ON %s %s FROM "%s";`, privList, keyword, identifier, role), nil
+		}
+		return fmt.Sprintf(`REVOKE %s ON %s %s FROM "%s";`, privList, keyword, identifier, role), nil
+	}
+
+	withGrantOption := ""
+	if grantOption {
+		withGrantOption = " WITH GRANT OPTION"
+	}
+	return fmt.Sprintf(`GRANT %s ON %s %s TO "%s"%s;`, privList, keyword, identifier, role, withGrantOption), nil
+}
+
+// qualifiedIdentifier quotes object as a single SQL identifier, or as a
+// schema-qualified pair ("schema"."name") when object contains a ".", e.g.
+// "reporting.orders" as produced by expandTableWildcards. Without this, a
+// schema-scoped wildcard like "reporting.*" would still emit GRANT/REVOKE
+// against an unqualified table name, letting search_path silently resolve
+// it to a same-named table in the wrong schema.
+func qualifiedIdentifier(object string) string {
+	if schema, name, ok := strings.Cut(object, "."); ok {
+		return fmt.Sprintf(`"%s"."%s"`, schema, name)
+	}
+	return fmt.Sprintf(`"%s"`, object)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// roleEdge is one row of pg_auth_members: member directly inherits role.
+type roleEdge struct {
+	Role   string
+	Member string
+}
+
+// getRoleMemberships returns every direct role-to-role grant in the
+// cluster, read from pg_auth_members.
+func getRoleMemberships(db *sql.DB) ([]roleEdge, error) {
+	rows, err := db.Query(`
+		SELECT r.rolname, m.rolname
+		FROM pg_auth_members am
+		JOIN pg_roles r ON r.oid = am.roleid
+		JOIN pg_roles m ON m.oid = am.member;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []roleEdge
+	for rows.Next() {
+		var e roleEdge
+		if err := rows.Scan(&e.Role, &e.Member); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+// getRoleAncestors walks pg_auth_members transitively and returns every
+// role that role inherits from, closest first. This is the same closure
+// PostgreSQL computes internally for pg_has_role(role, ..., 'USAGE').
+func getRoleAncestors(db *sql.DB, role string) ([]string, error) {
+	edges, err := getRoleMemberships(db)
+	if err != nil {
+		return nil, err
+	}
+	parentsOf := make(map[string][]string)
+	for _, e := range edges {
+		parentsOf[e.Member] = append(parentsOf[e.Member], e.Role)
+	}
+	return closure(role, parentsOf), nil
+}
+
+// getRoleDescendants walks pg_auth_members transitively and returns every
+// role that inherits from role, closest first.
+func getRoleDescendants(db *sql.DB, role string) ([]string, error) {
+	edges, err := getRoleMemberships(db)
+	if err != nil {
+		return nil, err
+	}
+	childrenOf := make(map[string][]string)
+	for _, e := range edges {
+		childrenOf[e.Role] = append(childrenOf[e.Role], e.Member)
+	}
+	return closure(role, childrenOf), nil
+}
+
+// closure does a breadth-first walk of adjacency starting at root,
+// returning every node reached (excluding root itself) in BFS order.
+func closure(root string, adjacency map[string][]string) []string {
+	var result []string
+	seen := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[cur] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			result = append(result, next)
+			queue = append(queue, next)
+		}
+	}
+	return result
+}
+
+// roleGrant grants parent's membership (and inherited privileges) to child,
+// equivalent to GRANT parent TO child.
+func roleGrant(dsn, parent, child string) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(fmt.Sprintf(`GRANT "%s" TO "%s";`, parent, child)); err != nil {
+		return fmt.Errorf("failed to grant role: %w", err)
+	}
+	fmt.Printf("Granted %s to %s\n", parent, child)
+	return nil
+}
+
+// roleRevoke revokes parent's membership from child, equivalent to
+// REVOKE parent FROM child.
+func roleRevoke(dsn, parent, child string) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(fmt.Sprintf(`REVOKE "%s" FROM "%s";`, parent, child)); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+	fmt.Printf("Revoked %s from %s\n", parent, child)
+	return nil
+}
+
+// roleTree prints the ancestor and descendant graph for role.
+func roleTree(dsn, role string) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ancestors, err := getRoleAncestors(db, role)
+	if err != nil {
+		return err
+	}
+	descendants, err := getRoleDescendants(db, role)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", role)
+	fmt.Println("  Inherits from:")
+	if len(ancestors) == 0 {
+		fmt.Println("    <none>")
+	}
+	for _, a := range ancestors {
+		fmt.Printf("    %s\n", a)
+	}
+	fmt.Println("  Inherited by:")
+	if len(descendants) == 0 {
+		fmt.Println("    <none>")
+	}
+	for _, d := range descendants {
+		fmt.Printf("    %s\n", d)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClosure(t *testing.T) {
+	// admin -> manager -> staff -> intern, with staff also reachable
+	// directly from admin (a diamond), so BFS must not revisit staff.
+	adjacency := map[string][]string{
+		"admin":   {"manager", "staff"},
+		"manager": {"staff"},
+		"staff":   {"intern"},
+	}
+
+	tests := []struct {
+		name string
+		root string
+		want []string
+	}{
+		{name: "root with a diamond-shaped closure", root: "admin", want: []string{"manager", "staff", "intern"}},
+		{name: "root partway down the chain", root: "manager", want: []string{"staff", "intern"}},
+		{name: "leaf with no children", root: "intern", want: nil},
+		{name: "root not present in adjacency", root: "ghost", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := closure(tt.root, adjacency)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("closure(%q, adjacency) = %v, want %v", tt.root, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClosureCycle(t *testing.T) {
+	// a <-> b is a cycle; closure must terminate and not report either
+	// node more than once.
+	adjacency := map[string][]string{
+		"a": {"b"},
+		"b": {"a"},
+	}
+
+	got := closure("a", adjacency)
+	want := []string{"b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("closure(\"a\", adjacency) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/alileza/rolo/acl"
+)
+
+// loadTableACLs reads and decodes pg_class.relacl for every table in one
+// round trip, instead of one query per table. When tableFilter is set, the
+// predicate is pushed into the query rather than applied in Go.
+//
+// See BenchmarkLoadTableACLsQueryCount (tableacl_bench_test.go, built with
+// -tags=integration) for a testcontainers-backed check that this issues
+// exactly one query regardless of table count.
+func loadTableACLs(db *sql.DB, tableFilter string) (map[string][]acl.Item, error) {
+	rows, err := db.Query(`
+		SELECT c.relname, c.relacl::text
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p')
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+		  AND ($1 = '' OR c.relname = $1);
+	`, tableFilter)
+	if err != nil {
+		return nil, err
+	}
+	return scanACLRows(rows)
+}
+
+// loadTableACLsBySchema is loadTableACLs's schema-qualified counterpart: it
+// keys each table's ACL by "schema.table" instead of the bare table name, so
+// callers that must disambiguate same-named tables living in different
+// schemas (plan/apply diffing wildcard-expanded grants) get the right one
+// instead of silently colliding on relname.
+func loadTableACLsBySchema(db *sql.DB) (map[string][]acl.Item, error) {
+	rows, err := db.Query(`
+		SELECT n.nspname || '.' || c.relname, c.relacl::text
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p')
+		  AND n.nspname NOT IN ('pg_catalog', 'information_schema');
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanACLRows(rows)
+}